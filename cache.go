@@ -0,0 +1,222 @@
+package relax
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// region Cache
+
+// Entry is a cached HTTP response: its status, headers, and a fully
+// buffered body, plus the validators needed to revalidate it once stale.
+type Entry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	ETag         string
+	LastModified string
+	Expires      time.Time
+}
+
+// response builds a fresh *http.Response from e, with a new
+// io.NopCloser body so the same Entry can be served to multiple callers.
+func (e *Entry) response() *http.Response {
+	return &http.Response{
+		StatusCode:    e.StatusCode,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+	}
+}
+
+// Cache is the storage a Client uses to keep HTTP responses across
+// requests. The default implementation wraps patrickmn/go-cache, but users
+// can plug in Redis or file-backed stores via WithCacheStore.
+type Cache interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry, ttl time.Duration)
+	Delete(key string)
+}
+
+// DefaultCacheTTL is the store TTL used for a response that carries a
+// validator (ETag or Last-Modified) but no max-age, or whose Cache-Control
+// says no-cache. It is always a concrete duration, never the
+// patrickmn/go-cache DefaultExpiration sentinel, so that Cache
+// implementations such as Redis or a file-backed store - which have no way
+// to interpret that sentinel - receive a TTL they can act on directly.
+const DefaultCacheTTL = 5 * time.Minute
+
+// goCacheStore is the default Cache implementation, backed by an in-memory
+// patrickmn/go-cache instance.
+type goCacheStore struct {
+	cache *cache.Cache
+}
+
+func newGoCacheStore(c *cache.Cache) *goCacheStore {
+	return &goCacheStore{cache: c}
+}
+
+func (s *goCacheStore) Get(key string) (*Entry, bool) {
+	v, found := s.cache.Get(key)
+	if !found {
+		return nil, false
+	}
+	return v.(*Entry), true
+}
+
+func (s *goCacheStore) Set(key string, entry *Entry, ttl time.Duration) {
+	s.cache.Set(key, entry, ttl)
+}
+
+func (s *goCacheStore) Delete(key string) {
+	s.cache.Delete(key)
+}
+
+// WithCacheStore allows the client to use a custom Cache implementation,
+// such as one backed by Redis or the filesystem, in place of the default
+// in-memory patrickmn/go-cache store
+func WithCacheStore(store Cache) ClientFeature {
+	return func(c *Client) {
+		c.Cache = store
+	}
+}
+
+// cacheControl holds the directives relevant to deciding whether and how
+// long to cache a response, parsed from its Cache-Control header.
+type cacheControl struct {
+	noStore   bool
+	noCache   bool
+	private   bool
+	maxAge    time.Duration
+	hasMaxAge bool
+}
+
+func parseCacheControl(header string) cacheControl {
+	var cc cacheControl
+	for _, part := range strings.Split(header, ",") {
+		directive := strings.TrimSpace(part)
+		lower := strings.ToLower(directive)
+		switch {
+		case lower == "no-store":
+			cc.noStore = true
+		case lower == "no-cache":
+			cc.noCache = true
+		case lower == "private":
+			cc.private = true
+		case strings.HasPrefix(lower, "max-age="):
+			if secs, err := strconv.Atoi(directive[len("max-age="):]); err == nil {
+				cc.maxAge = time.Duration(secs) * time.Second
+				cc.hasMaxAge = true
+			}
+		}
+	}
+	return cc
+}
+
+// entryFromResponse buffers resp's body and builds the Entry to cache for
+// it, returning ok=false when Cache-Control forbids caching the response or
+// when it carries neither a max-age nor a validator to revalidate against
+// later.
+func entryFromResponse(resp *http.Response) (entry *Entry, ttl time.Duration, ok bool) {
+	cc := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if cc.noStore || cc.private {
+		return nil, 0, false
+	}
+
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if !cc.hasMaxAge && etag == "" && lastModified == "" {
+		return nil, 0, false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, false
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	now := time.Now()
+	expires := now
+	ttl = DefaultCacheTTL
+	if cc.hasMaxAge && !cc.noCache {
+		expires = now.Add(cc.maxAge)
+		ttl = cc.maxAge
+	}
+
+	return &Entry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		ETag:         etag,
+		LastModified: lastModified,
+		Expires:      expires,
+	}, ttl, true
+}
+
+// freshness re-derives the expiry and store TTL to use for an entry that was
+// just revalidated via a 304, from the revalidation response's headers.
+func freshness(header http.Header) (expires time.Time, ttl time.Duration) {
+	cc := parseCacheControl(header.Get("Cache-Control"))
+	if cc.hasMaxAge && !cc.noCache {
+		return time.Now().Add(cc.maxAge), cc.maxAge
+	}
+	return time.Now(), DefaultCacheTTL
+}
+
+// cachedGet serves url from c.Cache when a fresh entry exists, revalidates a
+// stale entry with If-None-Match/If-Modified-Since when it carries a
+// validator, and otherwise issues a plain request and caches the result
+// according to its Cache-Control header.
+func (c *Client) cachedGet(ctx context.Context, url string, mods []Modifier) (*http.Response, error) {
+	if c.Cache == nil {
+		return nil, errors.New("relax: cache not defined")
+	}
+
+	entry, found := c.Cache.Get(url)
+	if found && time.Now().Before(entry.Expires) {
+		return entry.response(), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := c.DoWithContext(ctx, req, mods...)
+	if err != nil {
+		return nil, err
+	}
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		refreshed := *entry
+		expires, ttl := freshness(resp.Header)
+		refreshed.Expires = expires
+		c.Cache.Set(url, &refreshed, ttl)
+		return refreshed.response(), nil
+	}
+
+	if newEntry, ttl, ok := entryFromResponse(resp); ok {
+		c.Cache.Set(url, newEntry, ttl)
+	}
+	return resp, nil
+}
+
+// endregion
@@ -4,8 +4,8 @@ package relax
 
 import (
 	"context"
-	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -17,11 +17,27 @@ import (
 
 // Client represents an HTTP client with additional possible features
 type Client struct {
-	Cache       *cache.Cache
-	Credentials *clientcredentials.Config
-	HTTP        *http.Client
-	Limiter     *rate.Limiter
-	Timeout     time.Duration
+	Cache             Cache
+	Credentials       *clientcredentials.Config
+	HTTP              *http.Client
+	Limiter           *rate.Limiter
+	Timeout           time.Duration
+	MaxAttempts       int
+	MinRetryWait      time.Duration
+	MaxRetryWait      time.Duration
+	MaxRetryBodyBytes int64
+
+	HostLimiters     map[string]*rate.Limiter
+	hostLimitersMu   sync.RWMutex
+	defaultHostLimit rate.Limit
+	defaultHostBurst int
+
+	Encoder Encoder
+
+	Middlewares   []Middleware
+	tokens        *tokenManager
+	transportOnce sync.Once
+	transport     RoundTripper
 }
 
 // region Options
@@ -36,16 +52,28 @@ func FromClient(http *http.Client) ClientOption {
 	}
 }
 
-// FromDefaultClient creates a client from the default HTTP implementation
+// FromDefaultClient creates a client from a copy of the default HTTP
+// implementation. A copy is used, rather than http.DefaultClient itself, so
+// that features such as WithTimeout cannot mutate the process-wide default
+// client shared with unrelated code.
 func FromDefaultClient() ClientOption {
-	return FromClient(http.DefaultClient)
+	defaultClient := *http.DefaultClient
+	return FromClient(&defaultClient)
 }
 
-// FromConfig creates a client from a specified OAuth client configuration
+// FromConfig creates a client from a specified OAuth client configuration.
+// Tokens are no longer exchanged eagerly at construction time: instead the
+// client keeps a refreshable token cache, and New automatically wires
+// TokenRefreshMiddleware into the client's middleware chain so every request
+// carries a valid Authorization header without the caller having to do so
+// explicitly. Token errors therefore surface on the request that needs them
+// rather than on New.
 func FromConfig(cred *clientcredentials.Config) ClientOption {
 	return func(c *Client) {
 		c.Credentials = cred
-		c.HTTP = cred.Client(context.Background())
+		defaultClient := *http.DefaultClient
+		c.HTTP = &defaultClient
+		c.tokens = newTokenManager(cred.TokenSource(context.Background()))
 	}
 }
 
@@ -84,7 +112,7 @@ func WithDefaultTimeout(duration time.Duration) ClientFeature {
 // time and cleanup interval
 func WithCache(defaultExpiration, cleanupInterval time.Duration) ClientFeature {
 	return func(c *Client) {
-		c.Cache = cache.New(defaultExpiration, cleanupInterval)
+		c.Cache = newGoCacheStore(cache.New(defaultExpiration, cleanupInterval))
 	}
 }
 
@@ -106,16 +134,65 @@ func WithDefaultLimiter() ClientFeature {
 	return WithLimiter(10, 10) // TODO Adjust default limits
 }
 
+// WithHostLimiter allows the client to rate-limit requests per destination
+// host instead of sharing a single limiter across every host, lazily
+// creating a limiter from the given defaults the first time a host is seen
+func WithHostLimiter(defaults rate.Limit, burst int) ClientFeature {
+	return func(c *Client) {
+		c.hostLimitersMu.Lock()
+		defer c.hostLimitersMu.Unlock()
+		if c.HostLimiters == nil {
+			c.HostLimiters = make(map[string]*rate.Limiter)
+		}
+		c.defaultHostLimit = defaults
+		c.defaultHostBurst = burst
+	}
+}
+
+// WithHostLimiterFor allows the client to pin a specific limit and burst for
+// a single destination host, overriding whatever WithHostLimiter would
+// otherwise create for it
+func WithHostLimiterFor(host string, limit rate.Limit, burst int) ClientFeature {
+	return func(c *Client) {
+		c.hostLimitersMu.Lock()
+		defer c.hostLimitersMu.Unlock()
+		if c.HostLimiters == nil {
+			c.HostLimiters = make(map[string]*rate.Limiter)
+		}
+		c.HostLimiters[host] = rate.NewLimiter(limit, burst)
+	}
+}
+
+// WithRetry allows the client to automatically retry failed requests using
+// exponential backoff with jitter, honoring any Retry-After header the
+// server provides. maxBodyBytes bounds how much of a non-seekable request
+// body the client will buffer in memory to replay across retry attempts; a
+// non-positive value falls back to defaultMaxRetryBodyBytes.
+func WithRetry(maxAttempts int, minWait, maxWait time.Duration, maxBodyBytes int64) ClientFeature {
+	return func(c *Client) {
+		c.MaxAttempts = maxAttempts
+		c.MinRetryWait = minWait
+		c.MaxRetryWait = maxWait
+		c.MaxRetryBodyBytes = maxBodyBytes
+	}
+}
+
 // endregion
 
 // New creates a new client with a specified option along with the optional
-// features implemented.
+// features implemented. When the client was built via FromConfig or
+// FromCredentials, TokenRefreshMiddleware is automatically appended to the
+// client's middleware chain so every request is authenticated without the
+// caller having to wire it in via WithMiddleware themselves.
 func New(option ClientOption, feats ...ClientFeature) *Client {
 	client := &Client{}
 	option(client)
 	for _, feat := range feats {
 		feat(client)
 	}
+	if client.tokens != nil {
+		client.Middlewares = append(client.Middlewares, client.TokenRefreshMiddleware())
+	}
 	return client
 }
 
@@ -132,6 +209,7 @@ type Modifier func(m *Modifiers)
 type Modifiers struct {
 	UseCache   bool
 	UseLimiter bool
+	UseRetry   bool
 }
 
 // UseCache forces the client to attempt to pull from a cache, if the request
@@ -150,62 +228,107 @@ func UseLimiter(use bool) Modifier {
 	}
 }
 
+// UseRetry forces the client to retry the request with exponential backoff
+// on retryable failures, provided a WithRetry feature was configured.
+func UseRetry(use bool) Modifier {
+	return func(m *Modifiers) {
+		m.UseRetry = use
+	}
+}
+
 // endregion
 
 // Do sends an HTTP request and returns an HTTP response, with the option of
-// using modifiers to cache or limit the response
+// using modifiers to limit or retry the response. It is a thin
+// wrapper around DoWithContext using req's own context, kept for backwards
+// compatibility with callers that do not need explicit cancellation.
 func (c *Client) Do(req *http.Request, mods ...Modifier) (*http.Response, error) {
+	return c.DoWithContext(req.Context(), req, mods...)
+}
+
+// DoWithContext sends an HTTP request bound to ctx and returns an HTTP
+// response, with the option of using modifiers to limit or retry the
+// response; UseCache has no effect here, caching is done by Get and
+// GetWithContext. When UseRetry is set and the client was built with
+// WithRetry, the request body is buffered so it can be replayed across
+// attempts, and retryable failures are retried with exponential backoff for
+// up to MaxAttempts attempts in all, stopping early once ctx is done.
+func (c *Client) DoWithContext(ctx context.Context, req *http.Request, mods ...Modifier) (*http.Response, error) {
+	req = req.WithContext(ctx)
 	modifiers := &Modifiers{}
 	for _, mod := range mods {
 		mod(modifiers)
 	}
+	var limiter *rate.Limiter
 	if modifiers.UseLimiter {
-		if c.Limiter == nil {
-			err := errors.New("relax: limiter not defined")
+		var err error
+		limiter, err = c.limiterFor(req)
+		if err != nil {
 			return nil, err
 		}
-		err := c.Limiter.Wait(context.Background())
-		if err != nil {
+		if err := limiter.Wait(req.Context()); err != nil {
 			return nil, err
 		}
 	}
-	resp, err := c.HTTP.Do(req)
-	if err != nil {
-		return nil, err
+
+	if !modifiers.UseRetry || c.MaxAttempts <= 0 {
+		return c.roundTrip(req)
 	}
-	return resp, nil
-}
 
-// Get issues a GET to the specified URL, with the option of using modifiers to
-// cache or limit the response
-func (c *Client) Get(url string, mods ...Modifier) (resp *http.Response, err error) {
-	modifiers := &Modifiers{}
-	for _, mod := range mods {
-		mod(modifiers)
+	if err := c.bufferBody(req); err != nil {
+		return nil, err
 	}
-	if modifiers.UseCache {
-		if c.Cache == nil {
-			err := errors.New("relax: cache not defined")
-			return nil, err
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt < c.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if req.GetBody != nil {
+				body, gerr := req.GetBody()
+				if gerr != nil {
+					return nil, gerr
+				}
+				req.Body = body
+			}
+			if limiter != nil {
+				if err := limiter.Wait(req.Context()); err != nil {
+					return nil, err
+				}
+			}
 		}
-		cached, found := c.Cache.Get(url)
-		if found {
-			return cached.(*http.Response), nil
+
+		resp, err = c.roundTrip(req)
+
+		retryable := err != nil || (resp != nil && isRetryableStatus(resp.StatusCode))
+		if !retryable || attempt == c.MaxAttempts-1 {
+			return resp, err
 		}
-	}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
 
-	resp, err = c.Do(req)
-	if err == nil {
-		return nil, err
-	}
-	if modifiers.UseCache {
-		c.Cache.SetDefault(url, resp)
+		wait := c.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
 	}
 	return resp, err
 }
 
+// Get issues a GET to the specified URL, with the option of using modifiers
+// to cache, limit, or retry the response. It is a thin wrapper around
+// GetWithContext using context.Background(), kept for backwards
+// compatibility with callers that do not need explicit cancellation.
+func (c *Client) Get(url string, mods ...Modifier) (resp *http.Response, err error) {
+	return c.GetWithContext(context.Background(), url, mods...)
+}
+
 // endregion
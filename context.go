@@ -0,0 +1,126 @@
+package relax
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// region Encoding
+
+// Encoder converts an arbitrary value into a request body and its content
+// type, for use by the WithEncoder feature
+type Encoder func(v any) (io.Reader, string, error)
+
+// WithEncoder allows the client's Post/Put/Patch helpers to accept an
+// arbitrary value as a body, encoding it via the given function instead of
+// requiring callers to build an io.Reader themselves
+func WithEncoder(encoder Encoder) ClientFeature {
+	return func(c *Client) {
+		c.Encoder = encoder
+	}
+}
+
+// body resolves v into a request body and content type: nil and io.Reader
+// values pass through as-is, anything else is run through the client's
+// configured Encoder
+func (c *Client) body(v any) (io.Reader, string, error) {
+	switch b := v.(type) {
+	case nil:
+		return nil, "", nil
+	case io.Reader:
+		return b, "", nil
+	default:
+		if c.Encoder == nil {
+			return nil, "", errors.New("relax: encoder not defined")
+		}
+		return c.Encoder(v)
+	}
+}
+
+// newRequest builds a request for method, url, and body, setting the
+// Content-Type header when the body produced one
+func (c *Client) newRequest(ctx context.Context, method, url string, v any) (*http.Request, error) {
+	reader, contentType, err := c.body(v)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// endregion
+
+// region Context-aware operations
+
+// GetWithContext issues a GET to the specified URL, with the option of using
+// modifiers to cache, limit, or retry the response. A cached response is
+// served when still fresh per its Cache-Control header, and revalidated via
+// If-None-Match/If-Modified-Since when stale but carrying a validator.
+func (c *Client) GetWithContext(ctx context.Context, url string, mods ...Modifier) (*http.Response, error) {
+	modifiers := &Modifiers{}
+	for _, mod := range mods {
+		mod(modifiers)
+	}
+	if modifiers.UseCache {
+		return c.cachedGet(ctx, url, mods)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoWithContext(ctx, req, mods...)
+}
+
+// PostWithContext issues a POST to the specified URL with the given body,
+// with the option of using modifiers to limit or retry the response. The
+// body may be an io.Reader, nil, or a value encoded via WithEncoder.
+func (c *Client) PostWithContext(ctx context.Context, url string, body any, mods ...Modifier) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoWithContext(ctx, req, mods...)
+}
+
+// PutWithContext issues a PUT to the specified URL with the given body, with
+// the option of using modifiers to limit or retry the response. The body
+// may be an io.Reader, nil, or a value encoded via WithEncoder.
+func (c *Client) PutWithContext(ctx context.Context, url string, body any, mods ...Modifier) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoWithContext(ctx, req, mods...)
+}
+
+// PatchWithContext issues a PATCH to the specified URL with the given body,
+// with the option of using modifiers to limit or retry the response. The
+// body may be an io.Reader, nil, or a value encoded via WithEncoder.
+func (c *Client) PatchWithContext(ctx context.Context, url string, body any, mods ...Modifier) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodPatch, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoWithContext(ctx, req, mods...)
+}
+
+// DeleteWithContext issues a DELETE to the specified URL, with the option of
+// using modifiers to limit or retry the response
+func (c *Client) DeleteWithContext(ctx context.Context, url string, mods ...Modifier) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoWithContext(ctx, req, mods...)
+}
+
+// endregion
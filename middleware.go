@@ -0,0 +1,161 @@
+package relax
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// region Middleware
+
+// RoundTripper performs a single HTTP round trip for an already-built
+// *http.Request, analogous to http.RoundTripper but composable by Middleware
+type RoundTripper func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripper with additional behavior - logging,
+// tracing, metrics, header injection - without subclassing Client
+type Middleware func(next RoundTripper) RoundTripper
+
+// WithMiddleware allows the client to wrap every request in the given
+// middlewares, applied around c.HTTP.Do in the order they are given, so the
+// first middleware is outermost and sees the request first
+func WithMiddleware(mw ...Middleware) ClientFeature {
+	return func(c *Client) {
+		c.Middlewares = append(c.Middlewares, mw...)
+	}
+}
+
+// roundTripper builds, and caches, the RoundTripper that Do ultimately calls:
+// c.HTTP.Do wrapped by each configured Middleware, outermost first
+func (c *Client) roundTripper() RoundTripper {
+	c.transportOnce.Do(func() {
+		rt := RoundTripper(func(req *http.Request) (*http.Response, error) {
+			return c.HTTP.Do(req)
+		})
+		for i := len(c.Middlewares) - 1; i >= 0; i-- {
+			rt = c.Middlewares[i](rt)
+		}
+		c.transport = rt
+	})
+	return c.transport
+}
+
+// UserAgentMiddleware returns a Middleware that sets the User-Agent header
+// on every request to userAgent, and, when the client was built with
+// credentials, attaches an Authorization header for the current token
+func (c *Client) UserAgentMiddleware(userAgent string) Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("User-Agent", userAgent)
+			if c.tokens != nil {
+				token, err := c.tokens.Token()
+				if err != nil {
+					return nil, err
+				}
+				token.SetAuthHeader(req)
+			}
+			return next(req)
+		}
+	}
+}
+
+// TokenRefreshMiddleware returns a Middleware that attaches a valid OAuth2
+// access token to every request, transparently refreshing it in the
+// background once it is within tokenExpirySlack of expiring so callers
+// never block on a token exchange. It requires the client to have been
+// built via FromConfig or FromCredentials, which New detects and wires this
+// middleware in automatically.
+func (c *Client) TokenRefreshMiddleware() Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return func(req *http.Request) (*http.Response, error) {
+			if c.tokens == nil {
+				return nil, errors.New("relax: no credentials configured for token refresh")
+			}
+			token, err := c.tokens.Token()
+			if err != nil {
+				return nil, err
+			}
+			token.SetAuthHeader(req)
+			return next(req)
+		}
+	}
+}
+
+// endregion
+
+// region Token lifecycle
+
+// tokenExpirySlack is how far ahead of a token's expiry the client starts
+// refreshing it in the background, so requests never block on the exchange
+const tokenExpirySlack = 5 * time.Minute
+
+// tokenManager caches the OAuth2 token obtained from a token source,
+// refreshing it in the background once it enters its expiry slack window
+type tokenManager struct {
+	source oauth2.TokenSource
+
+	mu         sync.Mutex
+	token      *oauth2.Token
+	refreshing bool
+}
+
+func newTokenManager(source oauth2.TokenSource) *tokenManager {
+	return &tokenManager{source: source}
+}
+
+// Token returns a usable token, blocking on a token exchange only if no
+// token has been fetched yet or the cached one has already expired
+func (m *tokenManager) Token() (*oauth2.Token, error) {
+	m.mu.Lock()
+	token := m.token
+	m.mu.Unlock()
+
+	if token == nil || !token.Valid() {
+		return m.refresh()
+	}
+
+	if time.Until(token.Expiry) <= tokenExpirySlack {
+		m.maybeRefreshInBackground()
+	}
+
+	return token, nil
+}
+
+// refresh blocks on a synchronous token exchange and caches the result
+func (m *tokenManager) refresh() (*oauth2.Token, error) {
+	token, err := m.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.token = token
+	m.mu.Unlock()
+	return token, nil
+}
+
+// maybeRefreshInBackground starts a token exchange in a new goroutine,
+// unless one is already in flight
+func (m *tokenManager) maybeRefreshInBackground() {
+	m.mu.Lock()
+	if m.refreshing {
+		m.mu.Unlock()
+		return
+	}
+	m.refreshing = true
+	m.mu.Unlock()
+
+	go func() {
+		token, err := m.source.Token()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.refreshing = false
+		if err == nil {
+			m.token = token
+		}
+	}()
+}
+
+// endregion
@@ -0,0 +1,105 @@
+package relax
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// stubTokenSource is an oauth2.TokenSource test double that counts how many
+// times it was called and returns a canned token or error.
+type stubTokenSource struct {
+	calls int32
+	token *oauth2.Token
+	err   error
+}
+
+func (s *stubTokenSource) Token() (*oauth2.Token, error) {
+	atomic.AddInt32(&s.calls, 1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.token, nil
+}
+
+func TestTokenManagerFetchesOnFirstUse(t *testing.T) {
+	source := &stubTokenSource{token: &oauth2.Token{
+		AccessToken: "first",
+		Expiry:      time.Now().Add(time.Hour),
+	}}
+	m := newTokenManager(source)
+
+	token, err := m.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "first" {
+		t.Errorf("Token() = %q, want %q", token.AccessToken, "first")
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Errorf("source calls = %d, want 1", calls)
+	}
+}
+
+func TestTokenManagerServesCachedTokenOutsideSlack(t *testing.T) {
+	source := &stubTokenSource{token: &oauth2.Token{
+		AccessToken: "cached",
+		Expiry:      time.Now().Add(time.Hour),
+	}}
+	m := newTokenManager(source)
+
+	if _, err := m.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	token, err := m.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "cached" {
+		t.Errorf("Token() = %q, want %q", token.AccessToken, "cached")
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Errorf("source calls = %d, want 1 (no refetch outside slack)", calls)
+	}
+}
+
+func TestTokenManagerRefreshesInBackgroundWithinSlack(t *testing.T) {
+	source := &stubTokenSource{token: &oauth2.Token{
+		AccessToken: "stale",
+		Expiry:      time.Now().Add(tokenExpirySlack - time.Second),
+	}}
+	m := newTokenManager(source)
+
+	// The first call has no cached token and fetches synchronously; the
+	// background refresh is started by a later call within the slack window.
+	if _, err := m.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	token, err := m.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "stale" {
+		t.Errorf("Token() = %q, want %q (serves the near-expiry token while refreshing)", token.AccessToken, "stale")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&source.calls) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls < 2 {
+		t.Errorf("source calls = %d, want >= 2 (background refresh triggered)", calls)
+	}
+}
+
+func TestTokenManagerPropagatesFetchError(t *testing.T) {
+	source := &stubTokenSource{err: errors.New("token endpoint unreachable")}
+	m := newTokenManager(source)
+
+	if _, err := m.Token(); err == nil {
+		t.Fatal("Token() error = nil, want error from token source")
+	}
+}
@@ -0,0 +1,107 @@
+package relax
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// region Host limiting
+
+// limiterFor resolves the rate.Limiter to wait on for req, preferring a
+// per-host limiter when the client was configured with WithHostLimiter or
+// WithHostLimiterFor, and otherwise falling back to the client's single
+// shared Limiter
+func (c *Client) limiterFor(req *http.Request) (*rate.Limiter, error) {
+	if c.HostLimiters != nil {
+		return c.hostLimiter(req.URL.Host), nil
+	}
+	if c.Limiter == nil {
+		return nil, errors.New("relax: limiter not defined")
+	}
+	return c.Limiter, nil
+}
+
+// hostLimiter returns the limiter for host, lazily instantiating one from
+// the client's default limit and burst if this is the first time host has
+// been seen
+func (c *Client) hostLimiter(host string) *rate.Limiter {
+	c.hostLimitersMu.RLock()
+	limiter, ok := c.HostLimiters[host]
+	c.hostLimitersMu.RUnlock()
+	if ok {
+		return limiter
+	}
+
+	c.hostLimitersMu.Lock()
+	defer c.hostLimitersMu.Unlock()
+	if limiter, ok := c.HostLimiters[host]; ok {
+		return limiter
+	}
+	limiter = rate.NewLimiter(c.defaultHostLimit, c.defaultHostBurst)
+	c.HostLimiters[host] = limiter
+	return limiter
+}
+
+// roundTrip sends req through the client's middleware chain and, when the
+// client is using per-host limiters, tunes the limiter for req's host to the
+// server's advertised remaining quota before returning
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := c.roundTripper()(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.HostLimiters != nil {
+		c.tuneHostLimiter(req.URL.Host, resp)
+	}
+	return resp, nil
+}
+
+// tuneHostLimiter parses the X-RateLimit-Remaining and X-RateLimit-Reset
+// response headers, modeled on how Discord-style APIs advertise their
+// bucket state, and adjusts the host's limiter so the client converges on
+// the server's actual limits rather than whatever defaults it started with
+func (c *Client) tuneHostLimiter(host string, resp *http.Response) {
+	remaining, reset, ok := parseRateLimitHeaders(resp)
+	if !ok {
+		return
+	}
+	until := time.Until(reset)
+	if until <= 0 {
+		return
+	}
+
+	limiter := c.hostLimiter(host)
+	limiter.SetBurst(remaining + 1)
+	limiter.SetLimit(rate.Limit(float64(remaining) / until.Seconds()))
+}
+
+// parseRateLimitHeaders extracts the remaining request count and reset time
+// from a response's X-RateLimit-Remaining and X-RateLimit-Reset headers.
+// X-RateLimit-Reset is a Discord-style fractional epoch timestamp (e.g.
+// "1706141623.456"), so it is parsed as a float rather than an integer.
+func parseRateLimitHeaders(resp *http.Response) (remaining int, reset time.Time, ok bool) {
+	remainingHeader := resp.Header.Get("X-RateLimit-Remaining")
+	resetHeader := resp.Header.Get("X-RateLimit-Reset")
+	if remainingHeader == "" || resetHeader == "" {
+		return 0, time.Time{}, false
+	}
+
+	remaining, err := strconv.Atoi(remainingHeader)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	resetSecs, err := strconv.ParseFloat(resetHeader, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	reset = time.Unix(0, int64(resetSecs*float64(time.Second)))
+
+	return remaining, reset, true
+}
+
+// endregion
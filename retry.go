@@ -0,0 +1,90 @@
+package relax
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// region Retry
+
+// defaultMaxRetryBodyBytes is the max retry body size used when WithRetry is
+// given a non-positive maxBodyBytes.
+const defaultMaxRetryBodyBytes = 10 << 20 // 10 MiB
+
+// isRetryableStatus reports whether a response status code should be
+// retried: 429, 503, and any other 5xx except 501 Not Implemented, which
+// signals the server will never support the request.
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusNotImplemented:
+		return false
+	}
+	return code >= 500 && code < 600
+}
+
+// backoff computes the exponential backoff with jitter for a given attempt,
+// capped at c.MaxRetryWait: min(MaxRetryWait, MinRetryWait*2^attempt) plus up
+// to MinRetryWait of random jitter.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := float64(c.MinRetryWait) * math.Pow(2, float64(attempt))
+	if max := float64(c.MaxRetryWait); wait > max {
+		wait = max
+	}
+	wait += rand.Float64() * float64(c.MinRetryWait)
+	return time.Duration(wait)
+}
+
+// retryAfter parses a response's Retry-After header, which may be expressed
+// as either delta-seconds or an HTTP-date, returning the duration to wait
+// before retrying.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
+// bufferBody reads req.Body into memory, up to c.MaxRetryBodyBytes (or
+// defaultMaxRetryBodyBytes if unset), and installs req.GetBody so Do can
+// replay the request on retry.
+func (c *Client) bufferBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	max := c.MaxRetryBodyBytes
+	if max <= 0 {
+		max = defaultMaxRetryBodyBytes
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > max {
+		return errors.New("relax: request body too large to buffer for retry")
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// endregion
@@ -0,0 +1,88 @@
+package relax
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusTooManyRequests:     true,
+		http.StatusServiceUnavailable:  true,
+		http.StatusNotImplemented:      false,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusOK:                  false,
+		http.StatusNotFound:            false,
+	}
+	for code, want := range cases {
+		if got := isRetryableStatus(code); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	c := &Client{MinRetryWait: 100 * time.Millisecond, MaxRetryWait: time.Second}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		wait := c.backoff(attempt)
+		if wait < 0 {
+			t.Fatalf("backoff(%d) = %v, want non-negative", attempt, wait)
+		}
+		if wait > c.MaxRetryWait+c.MinRetryWait {
+			t.Fatalf("backoff(%d) = %v, want <= MaxRetryWait+MinRetryWait (%v)", attempt, wait, c.MaxRetryWait+c.MinRetryWait)
+		}
+	}
+}
+
+func TestRetryAfterDeltaSeconds(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"5"}}}
+	wait, ok := retryAfter(resp)
+	if !ok {
+		t.Fatal("retryAfter() ok = false, want true")
+	}
+	if wait != 5*time.Second {
+		t.Errorf("retryAfter() = %v, want 5s", wait)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	when := time.Now().Add(10 * time.Second)
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{when.UTC().Format(http.TimeFormat)}}}
+	wait, ok := retryAfter(resp)
+	if !ok {
+		t.Fatal("retryAfter() ok = false, want true")
+	}
+	if wait <= 0 || wait > 11*time.Second {
+		t.Errorf("retryAfter() = %v, want ~10s", wait)
+	}
+}
+
+func TestRetryAfterMissing(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := retryAfter(resp); ok {
+		t.Error("retryAfter() ok = true, want false for missing header")
+	}
+}
+
+func TestBufferBodyNoBody(t *testing.T) {
+	c := &Client{}
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+	req.Body = http.NoBody
+	if err := c.bufferBody(req); err != nil {
+		t.Fatalf("bufferBody() error = %v", err)
+	}
+}
+
+func TestBufferBodyTooLarge(t *testing.T) {
+	c := &Client{MaxRetryBodyBytes: 4}
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("too many bytes"))
+	req.GetBody = nil // force bufferBody to actually read the body, as it would for a non-seekable source
+	if err := c.bufferBody(req); err == nil {
+		t.Fatal("bufferBody() error = nil, want error for oversized body")
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxInFlightMiddleware caps the number of requests served concurrently by
+// next at max, responding with http.StatusTooManyRequests once that ceiling
+// is reached. Requests whose path matches longRunning bypass the semaphore
+// entirely, so streaming handlers do not starve the pool of tokens for their
+// entire lifetime.
+func maxInFlightMiddleware(next http.Handler, max int, longRunning *regexp.Regexp) http.Handler {
+	tokens := make(chan struct{}, max)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if longRunning != nil && longRunning.MatchString(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		select {
+		case tokens <- struct{}{}:
+			defer func() { <-tokens }()
+			next.ServeHTTP(w, req)
+		default:
+			http.Error(w, "too many requests in flight", http.StatusTooManyRequests)
+		}
+	})
+}
+
+// limiterEntry pairs a rate.Limiter with the time it was last used, so the
+// pool can evict limiters that have gone idle.
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// limiterPool is a mutex-guarded set of per-key rate limiters with
+// LRU-style eviction of keys that have been idle for longer than idleTimeout.
+type limiterPool struct {
+	mu          sync.Mutex
+	limit       rate.Limit
+	burst       int
+	idleTimeout time.Duration
+	entries     map[string]*limiterEntry
+}
+
+func newLimiterPool(limit rate.Limit, burst int, idleTimeout time.Duration) *limiterPool {
+	return &limiterPool{
+		limit:       limit,
+		burst:       burst,
+		idleTimeout: idleTimeout,
+		entries:     make(map[string]*limiterEntry),
+	}
+}
+
+// get returns the limiter for key, lazily instantiating one from the pool's
+// defaults the first time key is seen, and evicting any other limiters that
+// have been idle for longer than idleTimeout.
+func (p *limiterPool) get(key string) *rate.Limiter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range p.entries {
+		if k != key && now.Sub(e.lastUsed) > p.idleTimeout {
+			delete(p.entries, k)
+		}
+	}
+
+	e, ok := p.entries[key]
+	if !ok {
+		e = &limiterEntry{limiter: rate.NewLimiter(p.limit, p.burst)}
+		p.entries[key] = e
+	}
+	e.lastUsed = now
+	return e.limiter
+}
+
+// middleware rate-limits requests to next by req.Host when byHost is true, or
+// by req.RemoteAddr otherwise.
+func (p *limiterPool) middleware(next http.Handler, byHost bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.RemoteAddr
+		if byHost {
+			key = req.Host
+		}
+		if !p.get(key).Allow() {
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,128 @@
+// Package server provides an HTTP server implementation that mirrors the
+// relax client package, offering in-flight request limiting, long-running
+// endpoint exemptions, and per-client rate limiting so consumers of relax
+// can build symmetric client/server pipelines.
+package server
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// region Creation
+
+// Server represents an HTTP server with additional possible features
+type Server struct {
+	HTTP        *http.Server
+	Handler     http.Handler
+	MaxInFlight int
+	LongRunning *regexp.Regexp
+	limiters    *limiterPool
+}
+
+// region Options
+
+// ServerOption specifies how to create a server
+type ServerOption func(s *Server)
+
+// FromHandler creates a server from a specified HTTP handler
+func FromHandler(handler http.Handler) ServerOption {
+	return func(s *Server) {
+		s.Handler = handler
+	}
+}
+
+// FromServeMux creates a server from a specified http.ServeMux
+func FromServeMux(mux *http.ServeMux) ServerOption {
+	return FromHandler(mux)
+}
+
+// endregion
+
+// region Features
+
+// ServerFeature is a functional option for a server to specify additional
+// optional features on top of the default implementation
+type ServerFeature func(s *Server)
+
+// WithReadTimeout allows the server to timeout reading a request after a
+// specified duration
+func WithReadTimeout(duration time.Duration) ServerFeature {
+	return func(s *Server) {
+		s.HTTP.ReadTimeout = duration
+	}
+}
+
+// WithWriteTimeout allows the server to timeout writing a response after a
+// specified duration
+func WithWriteTimeout(duration time.Duration) ServerFeature {
+	return func(s *Server) {
+		s.HTTP.WriteTimeout = duration
+	}
+}
+
+// WithRequestTimeout wraps the server's handler with http.TimeoutHandler so
+// that any single request is aborted, and msg is returned to the client, once
+// it runs longer than duration
+func WithRequestTimeout(duration time.Duration, msg string) ServerFeature {
+	return func(s *Server) {
+		s.Handler = http.TimeoutHandler(s.Handler, duration, msg)
+	}
+}
+
+// WithMaxRequestsInFlight allows the server to cap the number of requests
+// served concurrently, responding with http.StatusTooManyRequests once the
+// ceiling is hit. Endpoints whose path matches longRunning are exempt, since
+// streaming handlers would otherwise hold a token for their entire lifetime.
+func WithMaxRequestsInFlight(max int, longRunning *regexp.Regexp) ServerFeature {
+	return func(s *Server) {
+		s.MaxInFlight = max
+		s.LongRunning = longRunning
+		s.Handler = maxInFlightMiddleware(s.Handler, max, longRunning)
+	}
+}
+
+// WithLimiter allows the server to rate-limit incoming requests by
+// req.RemoteAddr, or by req.Host when byHost is true, using a limiter per key
+// that is discarded once it has been idle for longer than idleTimeout.
+func WithLimiter(limit rate.Limit, burst int, byHost bool, idleTimeout time.Duration) ServerFeature {
+	return func(s *Server) {
+		s.limiters = newLimiterPool(limit, burst, idleTimeout)
+		s.Handler = s.limiters.middleware(s.Handler, byHost)
+	}
+}
+
+// endregion
+
+// New creates a new server with a specified option along with the optional
+// features implemented.
+func New(addr string, option ServerOption, feats ...ServerFeature) *Server {
+	server := &Server{
+		HTTP: &http.Server{Addr: addr},
+	}
+	option(server)
+	for _, feat := range feats {
+		feat(server)
+	}
+	server.HTTP.Handler = server.Handler
+	return server
+}
+
+// ListenAndServe starts the server on its configured address, applying
+// whatever timeouts and middleware were installed via its features.
+func (s *Server) ListenAndServe() error {
+	s.HTTP.Handler = s.Handler
+	return s.HTTP.ListenAndServe()
+}
+
+// Shutdown gracefully shuts down the server without interrupting active
+// connections.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.HTTP.Shutdown(ctx)
+}
+
+// endregion